@@ -0,0 +1,26 @@
+package age_encryption
+
+import (
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+// EncryptWriter wraps w so that everything subsequently written to the
+// returned io.WriteCloser is age-encrypted on the fly for recipientKeys.
+// Unlike EncryptFile, nothing is buffered on disk - callers Close the
+// returned writer once done to flush the last encrypted chunk; Close does
+// not close w.
+func EncryptWriter(w io.Writer, recipientKeys []string) (io.WriteCloser, error) {
+	recipients := make([]age.Recipient, 0, len(recipientKeys))
+	for _, key := range recipientKeys {
+		r, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient public key: %v", err)
+		}
+		recipients = append(recipients, r)
+	}
+
+	return age.Encrypt(w, recipients...)
+}
@@ -0,0 +1,9 @@
+package age_encryption
+
+import "filippo.io/age"
+
+// ParseIdentity parses an AGE-SECRET-KEY-1... string into the age.Identity
+// needed to decrypt a bundle that was encrypted for that key's recipient.
+func ParseIdentity(key string) (age.Identity, error) {
+	return age.ParseX25519Identity(key)
+}
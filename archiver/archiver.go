@@ -0,0 +1,60 @@
+// Package archiver builds bundle archives in one of several formats
+// behind a single interface, so the bundling pipeline doesn't need to
+// care which compressor/container it's writing to.
+package archiver
+
+import "io"
+
+// Supported archive formats, selectable per bundle via
+// BundleFileGroupParam.ArchiveFormat.
+const (
+	FormatZip    = "zip"
+	FormatTarGz  = "tar.gz"
+	FormatTarZst = "tar.zst"
+)
+
+// Archiver incrementally builds an archive of one format. Entries are
+// added in order and the archive is only valid once Close has been
+// called.
+type Archiver interface {
+	Add(name string, r io.Reader) error
+	Close() error
+}
+
+// SupportsPasscode reports whether format can password-protect entries by
+// itself. Formats that can't (the tar variants) must rely on
+// age_encryption.EncryptWriter wrapping the whole archive instead. An empty
+// format is the zip default, as in New.
+func SupportsPasscode(format string) bool {
+	switch format {
+	case FormatZip, "":
+		return true
+	default:
+		return false
+	}
+}
+
+// Extension returns the filename extension (including the leading dot)
+// hansip stores bundles under for format.
+func Extension(format string) string {
+	switch format {
+	case FormatTarGz:
+		return ".tar.gz"
+	case FormatTarZst:
+		return ".tar.zst"
+	default:
+		return ".zip"
+	}
+}
+
+// ContentType returns the MIME type hansip advertises for format.
+func ContentType(format string) string {
+	switch format {
+	case FormatTarGz:
+		return "application/gzip"
+	case FormatTarZst:
+		return "application/zstd"
+	default:
+		return "application/zip"
+	}
+}
@@ -0,0 +1,127 @@
+package archiver
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/yeka/zip"
+)
+
+func TestZipArchiverRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	arc := newZipArchiver(&buf, "s3cr3t!")
+
+	if err := arc.Add("hello.txt", bytes.NewReader([]byte("hello world"))); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := arc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if len(zr.File) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(zr.File))
+	}
+
+	entry := zr.File[0]
+	if !entry.IsEncrypted() {
+		t.Fatal("expected entry to be encrypted")
+	}
+	entry.SetPassword("s3cr3t!")
+
+	rc, err := entry.Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q, want %q", got, "hello world")
+	}
+}
+
+func TestTarGzArchiverRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	arc, err := newTarGzArchiver(&buf)
+	if err != nil {
+		t.Fatalf("newTarGzArchiver: %v", err)
+	}
+
+	if err := arc.Add("hello.txt", bytes.NewReader([]byte("hello world"))); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := arc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("tar.Next: %v", err)
+	}
+	if hdr.Name != "hello.txt" {
+		t.Fatalf("got name %q, want %q", hdr.Name, "hello.txt")
+	}
+
+	got, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q, want %q", got, "hello world")
+	}
+}
+
+func TestTarZstArchiverRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	arc, err := newTarZstArchiver(&buf)
+	if err != nil {
+		t.Fatalf("newTarZstArchiver: %v", err)
+	}
+
+	if err := arc.Add("hello.txt", bytes.NewReader([]byte("hello world"))); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := arc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	zr, err := zstd.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("zstd.NewReader: %v", err)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatalf("tar.Next: %v", err)
+	}
+	if hdr.Name != "hello.txt" {
+		t.Fatalf("got name %q, want %q", hdr.Name, "hello.txt")
+	}
+
+	got, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q, want %q", got, "hello world")
+	}
+}
@@ -0,0 +1,27 @@
+package archiver
+
+import (
+	"fmt"
+	"io"
+)
+
+// New builds the Archiver for format, writing into w. passcode is only
+// honored for formats that can protect entries themselves (zip); for the
+// rest it must be empty - callers reject Passcode up front and rely on
+// age-encrypting the whole archive instead.
+func New(format string, w io.Writer, passcode string) (Archiver, error) {
+	if !SupportsPasscode(format) && passcode != "" {
+		return nil, fmt.Errorf("archiver: %s does not support a passcode, encrypt the whole archive instead", format)
+	}
+
+	switch format {
+	case FormatTarGz:
+		return newTarGzArchiver(w)
+	case FormatTarZst:
+		return newTarZstArchiver(w)
+	case FormatZip, "":
+		return newZipArchiver(w, passcode), nil
+	default:
+		return nil, fmt.Errorf("archiver: unsupported format %q", format)
+	}
+}
@@ -0,0 +1,50 @@
+package archiver
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+)
+
+// tarGzArchiver has no per-entry password protection, bundles requesting
+// confidentiality must pair it with age recipients instead.
+type tarGzArchiver struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarGzArchiver(w io.Writer) (*tarGzArchiver, error) {
+	gz := gzip.NewWriter(w)
+	return &tarGzArchiver{gz: gz, tw: tar.NewWriter(gz)}, nil
+}
+
+func (a *tarGzArchiver) Add(name string, r io.Reader) error {
+	return addTarEntry(a.tw, name, r)
+}
+
+func (a *tarGzArchiver) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+// addTarEntry buffers r so its size is known up front, tar headers require
+// the entry size before any content is written.
+func addTarEntry(tw *tar.Writer, name string, r io.Reader) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: name,
+		Mode: 0o644,
+		Size: int64(len(buf)),
+	}); err != nil {
+		return err
+	}
+
+	_, err = tw.Write(buf)
+	return err
+}
@@ -0,0 +1,35 @@
+package archiver
+
+import (
+	"archive/tar"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// tarZstArchiver has no per-entry password protection, bundles requesting
+// confidentiality must pair it with age recipients instead.
+type tarZstArchiver struct {
+	zw *zstd.Encoder
+	tw *tar.Writer
+}
+
+func newTarZstArchiver(w io.Writer) (*tarZstArchiver, error) {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tarZstArchiver{zw: zw, tw: tar.NewWriter(zw)}, nil
+}
+
+func (a *tarZstArchiver) Add(name string, r io.Reader) error {
+	return addTarEntry(a.tw, name, r)
+}
+
+func (a *tarZstArchiver) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.zw.Close()
+}
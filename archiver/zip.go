@@ -0,0 +1,35 @@
+package archiver
+
+import (
+	"io"
+
+	"github.com/yeka/zip"
+)
+
+// zipArchiver AES-256-encrypts each entry with passcode, matching
+// hansip's original bundling behaviour.
+type zipArchiver struct {
+	w        *zip.Writer
+	passcode string
+}
+
+func newZipArchiver(w io.Writer, passcode string) *zipArchiver {
+	return &zipArchiver{w: zip.NewWriter(w), passcode: passcode}
+}
+
+func (a *zipArchiver) Add(name string, r io.Reader) error {
+	entry, err := a.w.Encrypt(name, a.passcode, zip.AES256Encryption)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, r)
+	return err
+}
+
+func (a *zipArchiver) Close() error {
+	if err := a.w.Flush(); err != nil {
+		return err
+	}
+	return a.w.Close()
+}
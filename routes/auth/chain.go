@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slaveofcode/hansip/repository/pg/models"
+)
+
+// Chain tries each Auther in order, returning the first successful
+// authentication.
+type Chain []Auther
+
+func (chain Chain) Authenticate(c *gin.Context) (*models.User, error) {
+	var lastErr = errors.New("no authenticator configured")
+	for _, a := range chain {
+		user, err := a.Authenticate(c)
+		if err == nil {
+			return user, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
@@ -2,55 +2,19 @@ package auth
 
 import (
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/slaveofcode/hansip/repository/pg"
-	"github.com/slaveofcode/hansip/repository/pg/models"
 )
 
-type requestHeader struct {
-	Authorization string `header:"Authorization"`
-}
+// CheckToken builds the token introspection endpoint handler: it reports
+// whether the bearer credential on the request's Authorization header is
+// valid against any of authers, tried in order. Pass NewDBTokenAuther(pgRepo)
+// to keep the original DB-only behaviour.
+func CheckToken(authers ...Auther) func(c *gin.Context) {
+	chain := Chain(authers)
 
-func CheckToken(pgRepo *pg.RepositoryPostgres) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		h := requestHeader{}
-		if err := c.ShouldBindHeader(&h); err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Unauthorized",
-			})
-			return
-		}
-
-		bearers := strings.Split(h.Authorization, " ")
-		if len(bearers) <= 1 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Unauthorized",
-			})
-			return
-		}
-
-		bearer := bearers[1]
-		db := pgRepo.GetDB()
-
-		var acct models.AccessToken
-		res := db.Where(&models.AccessToken{
-			Token: bearer,
-		}).First(&acct)
-
-		if res.RowsAffected <= 0 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Unauthorized",
-			})
-			return
-		}
-
-		if acct.TokenExpiredAt.Before(time.Now()) {
+		if _, err := chain.Authenticate(c); err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"success": false,
 				"message": "Unauthorized",
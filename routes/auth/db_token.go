@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slaveofcode/hansip/repository/pg"
+	"github.com/slaveofcode/hansip/repository/pg/models"
+)
+
+// DBTokenAuther authenticates requests against hansip's original opaque
+// bearer token scheme backed by models.AccessToken.
+type DBTokenAuther struct {
+	Repo *pg.RepositoryPostgres
+}
+
+// NewDBTokenAuther builds a DBTokenAuther against repo.
+func NewDBTokenAuther(repo *pg.RepositoryPostgres) *DBTokenAuther {
+	return &DBTokenAuther{Repo: repo}
+}
+
+func (a *DBTokenAuther) Name() string    { return "db-token" }
+func (a *DBTokenAuther) LoginPage() bool { return false }
+
+func (a *DBTokenAuther) Authenticate(c *gin.Context) (*models.User, error) {
+	token, ok := bearerToken(c)
+	if !ok {
+		return nil, errors.New("missing bearer token")
+	}
+
+	db := a.Repo.GetDB()
+
+	var acct models.AccessToken
+	if res := db.Where(&models.AccessToken{Token: token}).First(&acct); res.RowsAffected <= 0 {
+		return nil, errors.New("unknown token")
+	}
+
+	if acct.TokenExpiredAt.Before(time.Now()) {
+		return nil, errors.New("token expired")
+	}
+
+	var user models.User
+	if res := db.Where(&models.User{ID: acct.UserId}).First(&user); res.RowsAffected <= 0 {
+		return nil, errors.New("unknown user")
+	}
+
+	return &user, nil
+}
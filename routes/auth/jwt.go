@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/slaveofcode/hansip/repository/pg/models"
+)
+
+// JWTAuther authenticates requests bearing a stateless JWT, trusting its
+// claims rather than doing a DB lookup. Set exactly one of Secret
+// (HS256) or PublicKey (RS256).
+type JWTAuther struct {
+	Secret    []byte
+	PublicKey interface{}
+}
+
+// NewHS256Auther builds a JWTAuther that verifies HMAC-signed tokens.
+func NewHS256Auther(secret []byte) *JWTAuther {
+	return &JWTAuther{Secret: secret}
+}
+
+// NewRS256Auther builds a JWTAuther that verifies RSA-signed tokens.
+func NewRS256Auther(publicKey interface{}) *JWTAuther {
+	return &JWTAuther{PublicKey: publicKey}
+}
+
+func (a *JWTAuther) Name() string    { return "jwt" }
+func (a *JWTAuther) LoginPage() bool { return false }
+
+type jwtClaims struct {
+	Scope string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+func (a *JWTAuther) Authenticate(c *gin.Context) (*models.User, error) {
+	raw, ok := bearerToken(c)
+	if !ok {
+		return nil, errors.New("missing bearer token")
+	}
+
+	claims := &jwtClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if a.Secret == nil {
+				return nil, fmt.Errorf("HS256 not configured for this auther")
+			}
+			return a.Secret, nil
+		case *jwt.SigningMethodRSA:
+			if a.PublicKey == nil {
+				return nil, fmt.Errorf("RS256 not configured for this auther")
+			}
+			return a.PublicKey, nil
+		default:
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("missing sub claim")
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sub claim: %v", err)
+	}
+
+	return &models.User{ID: userId}, nil
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/slaveofcode/hansip/repository/pg/models"
+)
+
+// OIDCAuther authenticates requests bearing an ID token issued by an
+// upstream OIDC provider.
+type OIDCAuther struct {
+	Verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCAuther discovers issuerURL's OIDC configuration and builds an
+// OIDCAuther that verifies ID tokens issued to clientID.
+func NewOIDCAuther(ctx context.Context, issuerURL, clientID string) (*OIDCAuther, error) {
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCAuther{Verifier: provider.Verifier(&oidc.Config{ClientID: clientID})}, nil
+}
+
+func (a *OIDCAuther) Name() string    { return "oidc" }
+func (a *OIDCAuther) LoginPage() bool { return true }
+
+func (a *OIDCAuther) Authenticate(c *gin.Context) (*models.User, error) {
+	raw, ok := bearerToken(c)
+	if !ok {
+		return nil, errors.New("missing bearer token")
+	}
+
+	idToken, err := a.Verifier.Verify(c, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, errors.New("OIDC subject is not a hansip user id")
+	}
+
+	return &models.User{ID: userId}, nil
+}
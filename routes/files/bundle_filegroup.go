@@ -2,6 +2,7 @@ package files
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -9,30 +10,45 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/slaveofcode/hansip/age_encryption"
+	"github.com/slaveofcode/hansip/archiver"
 	"github.com/slaveofcode/hansip/repository/pg"
 	"github.com/slaveofcode/hansip/repository/pg/models"
 	"github.com/slaveofcode/hansip/routes/middleware"
-	appConfig "github.com/slaveofcode/hansip/utils/config"
+	"github.com/slaveofcode/hansip/storage"
 	"github.com/slaveofcode/hansip/utils/shortlink"
 	"github.com/spf13/viper"
-	"github.com/yeka/zip"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// upload status values persisted on FileGroup.UploadStatus while the
+// storage upload worker streams the archive in the background.
+const (
+	uploadStatusCompleted = "completed"
+	uploadStatusPending   = "pending"
+	uploadStatusFailed    = "failed"
+)
+
+const (
+	maxUploadAttempts    = 3
+	uploadAttemptBackoff = time.Second
+)
+
 type BundleFileGroupParam struct {
 	FileGroupId      uuid.UUID `json:"fileGroupId" binding:"required"`
 	ExpiredAt        string    `json:"expiredAt" binding:"required,datetime=2006-01-02T15:04:05Z07:00"` // format UTC: 2021-07-18T10:00:00.000Z
-	Passcode         string    `json:"passcode" binding:"required,gte=6,lte=100"`
+	Passcode         string    `json:"passcode" binding:"omitempty,gte=6,lte=100"`
 	DownloadPassword string    `json:"downloadPassword" binding:"omitempty,gte=6,lte=100"`
 	UserIds          []string  `json:"userIds" binding:"omitempty"`
+	// ArchiveFormat defaults to archiver.FormatZip. The tar variants don't
+	// support a per-file Passcode, pair them with UserIds instead so the
+	// whole archive gets age-encrypted.
+	ArchiveFormat string `json:"archiveFormat" binding:"omitempty,oneof=zip tar.gz tar.zst"`
 }
 
-func BundleFileGroup(repo *pg.RepositoryPostgres, s3Client *s3.Client) func(c *gin.Context) {
+func BundleFileGroup(repo *pg.RepositoryPostgres, backend storage.Backend) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		userId, err := middleware.GetUserId(c)
 		if err != nil {
@@ -52,6 +68,27 @@ func BundleFileGroup(repo *pg.RepositoryPostgres, s3Client *s3.Client) func(c *g
 			return
 		}
 
+		format := bodyParams.ArchiveFormat
+		if format == "" {
+			format = archiver.FormatZip
+		}
+
+		if archiver.SupportsPasscode(format) {
+			if len(bodyParams.Passcode) < 6 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"success": false,
+					"message": fmt.Sprintf("Passcode is required for %s bundles", format),
+				})
+				return
+			}
+		} else if bodyParams.Passcode != "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"message": fmt.Sprintf("%s bundles don't support a passcode, share to specific users for age encryption instead", format),
+			})
+			return
+		}
+
 		passcode, err := bcrypt.GenerateFromPassword([]byte(bodyParams.Passcode), bcrypt.DefaultCost)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
@@ -87,18 +124,6 @@ func BundleFileGroup(repo *pg.RepositoryPostgres, s3Client *s3.Client) func(c *g
 			return
 		}
 
-		bundledPath := filepath.FromSlash(viper.GetString("dirpaths.bundle"))
-		zipFileName := fileGroup.ID.String() + ".zip"
-		bundledFullPath := filepath.Join(bundledPath, zipFileName)
-		zipFile, err := os.Create(bundledFullPath)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"message": "Unable to bundle files:" + err.Error(),
-			})
-			return
-		}
-
 		userPubKeys := []string{}
 		if len(bodyParams.UserIds) > 0 {
 			// add user self first, so owner file can be downloaded too
@@ -118,55 +143,6 @@ func BundleFileGroup(repo *pg.RepositoryPostgres, s3Client *s3.Client) func(c *g
 		}
 
 		uploadPath := filepath.FromSlash(viper.GetString("dirpaths.upload"))
-		zipCompressor := zip.NewWriter(zipFile)
-		for _, item := range fileItems {
-			filePath := filepath.Join(uploadPath, item.Filename)
-
-			f, err := os.Open(filePath)
-			if err != nil {
-				// skip
-				log.Println("Error opening file at:", filePath)
-				continue
-			}
-
-			// add to compression
-			w, err := zipCompressor.Encrypt(item.Realname, bodyParams.Passcode, zip.AES256Encryption)
-			if err != nil {
-				log.Println("Error prepare zip file at:", filePath, err.Error())
-				f.Close()
-				continue
-			}
-
-			_, err = io.Copy(w, f)
-			if err != nil {
-				log.Println("Error zipping file at:", filePath, err.Error())
-				f.Close()
-				continue
-			}
-
-			f.Close()
-			os.Remove(filePath)
-		}
-
-		zipCompressor.Flush()
-		zipCompressor.Close()
-
-		fileGroup.FileKey = bundledFullPath
-
-		// set age encryption first if user exist
-		if len(userPubKeys) > 0 {
-			filePathEnc, err := age_encryption.EncryptFile(bundledFullPath, bundledPath, userPubKeys)
-			if err != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"success": false,
-					"message": "Unable to encrypt the file:" + err.Error(),
-				})
-				return
-			}
-
-			fileGroup.FileKey = filePathEnc
-			os.Remove(bundledFullPath)
-		}
 
 		expDate, err := time.Parse(time.RFC3339, bodyParams.ExpiredAt)
 		if err != nil {
@@ -174,9 +150,12 @@ func BundleFileGroup(repo *pg.RepositoryPostgres, s3Client *s3.Client) func(c *g
 		}
 
 		now := time.Now()
+		fileGroup.FileKey = fileGroup.ID.String() + archiver.Extension(format)
+		fileGroup.ArchiveFormat = format
 		fileGroup.ArchivePasscode = string(passcode)
 		fileGroup.BundledAt = &now
 		fileGroup.ExpiredAt = &expDate
+		fileGroup.UploadStatus = uploadStatusPending
 
 		res = db.Save(&fileGroup)
 		if res.Error != nil || res.RowsAffected <= 0 {
@@ -187,33 +166,10 @@ func BundleFileGroup(repo *pg.RepositoryPostgres, s3Client *s3.Client) func(c *g
 			return
 		}
 
-		go func(filePath string) {
-			keyName := filepath.Base(filePath)
-			bundledFile, err := os.Open(filePath)
-			if err != nil {
-				log.Printf("Error reading bundled file at %s, is the file removed? %s", filePath, err.Error())
-				return
-			}
-			defer bundledFile.Close()
-
-			_, err = s3Client.PutObject(context.Background(), &s3.PutObjectInput{
-				Bucket:  aws.String(appConfig.GetS3Bucket()),
-				Key:     &keyName,
-				Body:    bundledFile,
-				Expires: fileGroup.ExpiredAt, // cache expiration
-			})
-
-			if err == nil {
-				fileGroup.FileKey = keyName
-				db.Save(&fileGroup)
-
-				// remove local file because already uploaded to S3
-				os.Remove(filePath)
-				return
-			}
-
-			log.Println("error S3 upload", err)
-		}(fileGroup.FileKey)
+		// stream the archive straight into the storage backend, the
+		// request doesn't wait for it; FileGroup.UploadStatus is updated
+		// once the worker settles
+		go uploadBundleToStorage(repo, backend, fileGroup, fileItems, uploadPath, bodyParams.Passcode, userPubKeys)
 
 		pinCode := ""
 
@@ -248,3 +204,111 @@ func BundleFileGroup(repo *pg.RepositoryPostgres, s3Client *s3.Client) func(c *g
 		})
 	}
 }
+
+// uploadBundleToStorage streams the archive into the backend, retrying the
+// whole pipeline a few times on transient failure before giving up and
+// marking the file group as failed.
+func uploadBundleToStorage(repo *pg.RepositoryPostgres, backend storage.Backend, fileGroup models.FileGroup, fileItems []models.FileItem, uploadPath, passcode string, recipientKeys []string) {
+	db := repo.GetDB()
+
+	var lastErr error
+	for attempt := 1; attempt <= maxUploadAttempts; attempt++ {
+		if lastErr = streamBundleUpload(backend, fileGroup, fileItems, uploadPath, passcode, recipientKeys); lastErr == nil {
+			fileGroup.UploadStatus = uploadStatusCompleted
+			db.Save(&fileGroup)
+
+			// only now that the backend has confirmed the archive landed
+			// is it safe to remove the sources, a failed/retried attempt
+			// must be able to re-read every file from scratch
+			removeSourceFiles(fileItems, uploadPath)
+			return
+		}
+
+		log.Printf("upload attempt %d/%d for file group %s failed: %s", attempt, maxUploadAttempts, fileGroup.ID.String(), lastErr.Error())
+		time.Sleep(time.Duration(attempt) * uploadAttemptBackoff)
+	}
+
+	fileGroup.UploadStatus = uploadStatusFailed
+	db.Save(&fileGroup)
+	log.Println("giving up uploading file group", fileGroup.ID.String(), lastErr)
+}
+
+// removeSourceFiles deletes each fileItem's upload from uploadPath. Only
+// called once the archive built from them has been durably stored.
+func removeSourceFiles(fileItems []models.FileItem, uploadPath string) {
+	for _, item := range fileItems {
+		os.Remove(filepath.Join(uploadPath, item.Filename))
+	}
+}
+
+// streamBundleUpload pipes the archive writer straight into backend.Put, so
+// even multi-GB bundles never touch local disk when a remote backend is in
+// use.
+func streamBundleUpload(backend storage.Backend, fileGroup models.FileGroup, fileItems []models.FileItem, uploadPath, passcode string, recipientKeys []string) error {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(writeArchive(pw, fileGroup.ArchiveFormat, fileItems, uploadPath, passcode, recipientKeys))
+	}()
+
+	contentType := archiver.ContentType(fileGroup.ArchiveFormat)
+	if len(recipientKeys) > 0 {
+		// age-wrapped, the whole archive is opaque binary regardless of
+		// the inner format
+		contentType = "application/octet-stream"
+	}
+
+	return backend.Put(context.Background(), fileGroup.FileKey, pr, storage.PutMeta{
+		ContentType: contentType,
+		Expires:     fileGroup.ExpiredAt,
+	})
+}
+
+// writeArchive builds an archiver.Archiver of format writing into w,
+// wrapping w with age encryption first when recipientKeys is non-empty.
+// Source files are left untouched - only removeSourceFiles, called once
+// the upload is confirmed, may delete them - so a failed attempt can
+// retry from scratch. On success the archive, and the age stream if any,
+// are closed before returning; w itself is closed only when nothing wraps
+// it. On failure w is left open so the caller can close it with the
+// returned error.
+func writeArchive(w io.WriteCloser, format string, fileItems []models.FileItem, uploadPath, passcode string, recipientKeys []string) error {
+	target := io.WriteCloser(w)
+	if len(recipientKeys) > 0 {
+		encWriter, err := age_encryption.EncryptWriter(w, recipientKeys)
+		if err != nil {
+			return err
+		}
+		target = encWriter
+	}
+
+	arc, err := archiver.New(format, target, passcode)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range fileItems {
+		filePath := filepath.Join(uploadPath, item.Filename)
+
+		f, err := os.Open(filePath)
+		if err != nil {
+			// skip
+			log.Println("Error opening file at:", filePath)
+			continue
+		}
+
+		if err := arc.Add(item.Realname, f); err != nil {
+			log.Println("Error archiving file at:", filePath, err.Error())
+			f.Close()
+			continue
+		}
+
+		f.Close()
+	}
+
+	if err := arc.Close(); err != nil {
+		return err
+	}
+
+	return target.Close()
+}
@@ -0,0 +1,73 @@
+package files
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slaveofcode/hansip/repository/pg/models"
+)
+
+// TestWriteArchiveDoesNotDeleteSourceFiles guards against the data-loss
+// bug where writeArchive removed each source file as soon as it was
+// streamed into the archive, before the backend upload had confirmed
+// success - so a failed first attempt made every retry build from
+// already-deleted files. Source files must survive writeArchive; only
+// removeSourceFiles, called after a confirmed upload, may delete them.
+func TestWriteArchiveDoesNotDeleteSourceFiles(t *testing.T) {
+	uploadPath := t.TempDir()
+
+	fileItems := []models.FileItem{
+		{Filename: "a.bin", Realname: "a.txt"},
+		{Filename: "b.bin", Realname: "b.txt"},
+	}
+
+	for _, item := range fileItems {
+		if err := os.WriteFile(filepath.Join(uploadPath, item.Filename), []byte("content-"+item.Filename), 0o644); err != nil {
+			t.Fatalf("seeding %s: %v", item.Filename, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeArchive(nopWriteCloser{&buf}, "", fileItems, uploadPath, "s3cr3t!", nil); err != nil {
+		t.Fatalf("writeArchive: %v", err)
+	}
+
+	for _, item := range fileItems {
+		if _, err := os.Stat(filepath.Join(uploadPath, item.Filename)); err != nil {
+			t.Fatalf("expected %s to still exist after writeArchive, got: %v", item.Filename, err)
+		}
+	}
+}
+
+func TestRemoveSourceFilesDeletesAfterSuccess(t *testing.T) {
+	uploadPath := t.TempDir()
+
+	fileItems := []models.FileItem{
+		{Filename: "a.bin"},
+		{Filename: "b.bin"},
+	}
+
+	for _, item := range fileItems {
+		if err := os.WriteFile(filepath.Join(uploadPath, item.Filename), []byte("content"), 0o644); err != nil {
+			t.Fatalf("seeding %s: %v", item.Filename, err)
+		}
+	}
+
+	removeSourceFiles(fileItems, uploadPath)
+
+	for _, item := range fileItems {
+		if _, err := os.Stat(filepath.Join(uploadPath, item.Filename)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got err: %v", item.Filename, err)
+		}
+	}
+}
+
+// nopWriteCloser adapts an io.Writer for writeArchive's io.WriteCloser
+// parameter in tests where nothing downstream needs closing.
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (nopWriteCloser) Close() error { return nil }
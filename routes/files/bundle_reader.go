@@ -0,0 +1,166 @@
+package files
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"filippo.io/age"
+	"github.com/gin-gonic/gin"
+	"github.com/slaveofcode/hansip/age_encryption"
+	"github.com/slaveofcode/hansip/archiver"
+	"github.com/slaveofcode/hansip/repository/pg"
+	"github.com/slaveofcode/hansip/repository/pg/models"
+	"github.com/slaveofcode/hansip/storage"
+	"github.com/slaveofcode/hansip/utils/shortlink"
+	"github.com/yeka/zip"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// errUnsupportedFormat is returned when selective retrieval is attempted
+// on a bundle that isn't a zip, only zip lets us seek to and decrypt one
+// entry at a time.
+var errUnsupportedFormat = errors.New("selective file retrieval is only supported for zip bundles")
+
+// ageIdentityHeader carries the recipient's AGE-SECRET-KEY-1... identity
+// for bundles shared to specific users. It's a long-lived per-user secret
+// (reused across every bundle shared with that user), so it travels in a
+// header rather than a query string to keep it out of access/proxy logs.
+const ageIdentityHeader = "X-Age-Identity"
+
+// openBundleForRead resolves code to its FileGroup, checks it hasn't
+// expired, verifies passcode/pin against it, confirms the background
+// upload has actually completed, then opens the archive as a zip reader -
+// writing an error response itself and returning ok=false on any failure.
+// Callers just check ok and, on success, defer closeBundleZip(tmp).
+func openBundleForRead(c *gin.Context, repo *pg.RepositoryPostgres, backend storage.Backend) (fileGroup models.FileGroup, zr *zip.Reader, tmp *os.File, ok bool) {
+	code := c.Param("shortlink")
+
+	db := repo.GetDB()
+
+	fileGroup, err := shortlink.ResolveFileGroup(code, db)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "Invalid or expired link",
+		})
+		return fileGroup, nil, nil, false
+	}
+
+	if fileGroup.ExpiredAt != nil && fileGroup.ExpiredAt.Before(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusGone, gin.H{
+			"success": false,
+			"message": "Link has expired",
+		})
+		return fileGroup, nil, nil, false
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(fileGroup.ArchivePasscode), []byte(c.Query("passcode"))); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid passcode",
+		})
+		return fileGroup, nil, nil, false
+	}
+
+	if err := shortlink.VerifyPin(code, c.Query("downloadPassword"), db); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid download password",
+		})
+		return fileGroup, nil, nil, false
+	}
+
+	if fileGroup.UploadStatus != uploadStatusCompleted {
+		status, message := http.StatusAccepted, "Bundle is still being prepared, try again shortly"
+		if fileGroup.UploadStatus == uploadStatusFailed {
+			status, message = http.StatusInternalServerError, "Bundling failed, create a new bundle"
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"success": false,
+			"message": message,
+		})
+		return fileGroup, nil, nil, false
+	}
+
+	zr, tmp, err = openBundleZip(c, backend, fileGroup, c.GetHeader(ageIdentityHeader))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == errUnsupportedFormat {
+			status = http.StatusBadRequest
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"success": false,
+			"message": "Unable to read bundle:" + err.Error(),
+		})
+		return fileGroup, nil, nil, false
+	}
+
+	return fileGroup, zr, tmp, true
+}
+
+// openBundleZip fetches fileGroup's archive from backend, age-decrypting
+// it first when it was shared to specific users, and opens it as a zip
+// reader. The archive is spooled into a temp file since zip.NewReader
+// needs an io.ReaderAt - the caller must Close and remove it (see
+// closeBundleZip).
+func openBundleZip(c *gin.Context, backend storage.Backend, fileGroup models.FileGroup, ageIdentity string) (*zip.Reader, *os.File, error) {
+	if fileGroup.ArchiveFormat != "" && fileGroup.ArchiveFormat != archiver.FormatZip {
+		return nil, nil, errUnsupportedFormat
+	}
+
+	rc, err := backend.Get(c, fileGroup.FileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rc.Close()
+
+	var src io.Reader = rc
+	if len(fileGroup.SharedToUserIds) > 0 {
+		identity, err := age_encryption.ParseIdentity(ageIdentity)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		src, err = age.Decrypt(rc, identity)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "hansip-bundle-*.zip")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, nil, err
+	}
+
+	stat, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, nil, err
+	}
+
+	zr, err := zip.NewReader(tmp, stat.Size())
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, nil, err
+	}
+
+	return zr, tmp, nil
+}
+
+// closeBundleZip releases the temp file backing a zip.Reader opened by
+// openBundleZip.
+func closeBundleZip(tmp *os.File) {
+	tmp.Close()
+	os.Remove(tmp.Name())
+}
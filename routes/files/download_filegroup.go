@@ -0,0 +1,104 @@
+package files
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slaveofcode/hansip/archiver"
+	"github.com/slaveofcode/hansip/repository/pg"
+	"github.com/slaveofcode/hansip/storage"
+	"github.com/slaveofcode/hansip/utils/shortlink"
+)
+
+// DownloadFileGroup resolves a shortlink code to its bundled archive. When
+// the backend can issue a presigned URL (S3), it redirects the client
+// straight to it, scoped to the bundle's ExpiredAt. Backends that can't
+// presign (local disk, WebDAV) are streamed through instead.
+func DownloadFileGroup(repo *pg.RepositoryPostgres, backend storage.Backend) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		code := c.Param("shortlink")
+
+		db := repo.GetDB()
+
+		fileGroup, err := shortlink.ResolveFileGroup(code, db)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "Invalid or expired link",
+			})
+			return
+		}
+
+		if fileGroup.ExpiredAt != nil && fileGroup.ExpiredAt.Before(time.Now()) {
+			c.AbortWithStatusJSON(http.StatusGone, gin.H{
+				"success": false,
+				"message": "Link has expired",
+			})
+			return
+		}
+
+		if err := shortlink.VerifyPin(code, c.Query("downloadPassword"), db); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"message": "Invalid download password",
+			})
+			return
+		}
+
+		if fileGroup.UploadStatus != uploadStatusCompleted {
+			status, message := http.StatusAccepted, "Bundle is still being prepared, try again shortly"
+			if fileGroup.UploadStatus == uploadStatusFailed {
+				status, message = http.StatusInternalServerError, "Bundling failed, create a new bundle"
+			}
+			c.AbortWithStatusJSON(status, gin.H{
+				"success": false,
+				"message": message,
+			})
+			return
+		}
+
+		filename := fileGroup.ID.String() + archiver.Extension(fileGroup.ArchiveFormat)
+		contentType := archiver.ContentType(fileGroup.ArchiveFormat)
+		if len(fileGroup.SharedToUserIds) > 0 {
+			// age-encrypted archives are opaque binary, not a plain zip
+			contentType = "application/octet-stream"
+		}
+
+		// ExpiredAt is set by BundleFileGroup before the shortlink is ever
+		// created, so a resolved code always carries one - safe to
+		// dereference here.
+		url, err := backend.Presign(c, fileGroup.FileKey, time.Until(*fileGroup.ExpiredAt), storage.PresignOptions{
+			ResponseContentDisposition: `attachment; filename="` + filename + `"`,
+			ResponseContentType:        contentType,
+		})
+		if err == nil {
+			c.Redirect(http.StatusFound, url)
+			return
+		}
+
+		if !errors.Is(err, storage.ErrPresignUnsupported) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Unable to create download URL:" + err.Error(),
+			})
+			return
+		}
+
+		rc, err := backend.Get(c, fileGroup.FileKey)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Unable to read bundled file:" + err.Error(),
+			})
+			return
+		}
+		defer rc.Close()
+
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Header("Content-Type", contentType)
+		io.Copy(c.Writer, rc)
+	}
+}
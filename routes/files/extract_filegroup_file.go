@@ -0,0 +1,62 @@
+package files
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slaveofcode/hansip/repository/pg"
+	"github.com/slaveofcode/hansip/storage"
+	"github.com/yeka/zip"
+)
+
+// ExtractFileGroupFile decrypts and streams a single entry out of a
+// bundle, so recipients can grab one file from the browser without a
+// client-side decrypt tool.
+func ExtractFileGroupFile(repo *pg.RepositoryPostgres, backend storage.Backend) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		_, zr, tmp, ok := openBundleForRead(c, repo, backend)
+		if !ok {
+			return
+		}
+		defer closeBundleZip(tmp)
+
+		name := c.Param("name")
+
+		var entry *zip.File
+		for _, f := range zr.File {
+			if f.Name == name {
+				entry = f
+				break
+			}
+		}
+
+		if entry == nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"message": "File not found in bundle",
+			})
+			return
+		}
+
+		if entry.IsEncrypted() {
+			entry.SetPassword(c.Query("passcode"))
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Unable to decrypt file:" + err.Error(),
+			})
+			return
+		}
+		defer rc.Close()
+
+		c.Header("Content-Disposition", `attachment; filename="`+filepath.Base(entry.Name)+`"`)
+		c.Header("Content-Length", strconv.FormatUint(entry.UncompressedSize64, 10))
+		io.Copy(c.Writer, rc)
+	}
+}
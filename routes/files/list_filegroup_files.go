@@ -0,0 +1,34 @@
+package files
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/slaveofcode/hansip/repository/pg"
+	"github.com/slaveofcode/hansip/storage"
+)
+
+// ListFileGroupFiles lists the entries inside a bundle without requiring
+// the recipient to download and decrypt the whole archive first.
+func ListFileGroupFiles(repo *pg.RepositoryPostgres, backend storage.Backend) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		_, zr, tmp, ok := openBundleForRead(c, repo, backend)
+		if !ok {
+			return
+		}
+		defer closeBundleZip(tmp)
+
+		entries := make([]gin.H, 0, len(zr.File))
+		for _, f := range zr.File {
+			entries = append(entries, gin.H{
+				"name": f.Name,
+				"size": f.UncompressedSize64,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    entries,
+		})
+	}
+}
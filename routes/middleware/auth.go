@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/slaveofcode/hansip/routes/auth"
+)
+
+// authChain is the Auther chain every protected route authenticates
+// against. It's nil until SetAuthChain runs during startup, so a missing
+// wiring call fails closed instead of silently accepting every request.
+var authChain auth.Chain
+
+// SetAuthChain configures the Auther chain GetUserId authenticates
+// against. Call once during server startup, e.g.:
+//
+//	oidcAuther, err := auth.NewOIDCAuther(ctx, issuerURL, clientID)
+//	// handle err
+//	middleware.SetAuthChain(auth.Chain{
+//		auth.NewDBTokenAuther(repo),
+//		auth.NewHS256Auther(jwtSecret),
+//		oidcAuther,
+//	})
+func SetAuthChain(chain auth.Chain) {
+	authChain = chain
+}
+
+// GetUserId authenticates the request against the configured Auther chain
+// and returns the resolved user's ID. Every protected route (BundleFileGroup,
+// etc.) calls this before touching the database, so it's the single place
+// DB-token, JWT and OIDC credentials all get accepted.
+func GetUserId(c *gin.Context) (uuid.UUID, error) {
+	if authChain == nil {
+		return uuid.UUID{}, errors.New("no authenticator configured")
+	}
+
+	user, err := authChain.Authenticate(c)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return user.ID, nil
+}
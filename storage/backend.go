@@ -0,0 +1,59 @@
+// Package storage abstracts where bundled archives actually live so the
+// rest of hansip (bundling, download) never talks to a concrete SDK client
+// directly. Select an implementation through the "storage.backend" config
+// key, see New.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned by Get/Stat when key doesn't exist in the
+// backend.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrPresignUnsupported is returned by Presign on backends that have no
+// notion of a client-fetchable signed URL (local disk, WebDAV). Callers
+// should fall back to streaming through Get instead.
+var ErrPresignUnsupported = errors.New("storage: backend does not support presigned URLs")
+
+// PutMeta carries the metadata callers want attached to an object. Not
+// every backend honours every field, implementations ignore what they
+// can't express.
+type PutMeta struct {
+	ContentType string
+	Expires     *time.Time
+}
+
+// Info describes an object already stored in a backend.
+type Info struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// PresignOptions carries the response headers callers want the presigned
+// URL to force on the client's GET, so a redirected download still looks
+// like it came from hansip rather than the bucket. Not every backend
+// supports presigning at all (see ErrPresignUnsupported), let alone these
+// fields, implementations ignore what they can't express.
+type PresignOptions struct {
+	ResponseContentDisposition string
+	ResponseContentType        string
+}
+
+// Backend is the storage abstraction every delivery backend implements.
+type Backend interface {
+	// Put writes r to key, streaming rather than buffering where the
+	// underlying implementation allows it.
+	Put(ctx context.Context, key string, r io.Reader, meta PutMeta) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Presign returns a time-limited URL clients can fetch key from
+	// directly, scoped to at most ttl. Returns ErrPresignUnsupported when
+	// the backend can't do this.
+	Presign(ctx context.Context, key string, ttl time.Duration, opts PresignOptions) (string, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (Info, error)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalBackend stores archives as plain files under RootDir, for
+// self-hosters running hansip without any object storage.
+type LocalBackend struct {
+	RootDir string
+}
+
+// NewLocalBackend builds a LocalBackend rooted at rootDir. rootDir is
+// created on first Put if it doesn't already exist.
+func NewLocalBackend(rootDir string) *LocalBackend {
+	return &LocalBackend{RootDir: rootDir}
+}
+
+func (b *LocalBackend) path(key string) string {
+	return filepath.Join(b.RootDir, filepath.FromSlash(key))
+}
+
+func (b *LocalBackend) Put(ctx context.Context, key string, r io.Reader, meta PutMeta) error {
+	dest := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+func (b *LocalBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(b.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Presign always returns ErrPresignUnsupported, local disk has no notion
+// of a client-fetchable signed URL.
+func (b *LocalBackend) Presign(ctx context.Context, key string, ttl time.Duration, opts PresignOptions) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+func (b *LocalBackend) Delete(ctx context.Context, key string) error {
+	err := os.Remove(b.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (b *LocalBackend) Stat(ctx context.Context, key string) (Info, error) {
+	fi, err := os.Stat(b.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return Info{}, ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Info{Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// maxPresignTTL mirrors the S3 presigned URL hard limit, 7 days from the
+// time of signing.
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// S3Backend stores archives in an S3 (or S3-compatible) bucket, uploading
+// through manager.Uploader so callers can hand it an unbounded io.Reader
+// (e.g. the read side of an io.Pipe) without buffering it first.
+type S3Backend struct {
+	Client      *s3.Client
+	Bucket      string
+	PartSizeMb  int
+	Concurrency int
+}
+
+// NewS3Backend builds an S3Backend for bucket using client.
+func NewS3Backend(client *s3.Client, bucket string) *S3Backend {
+	return &S3Backend{Client: client, Bucket: bucket}
+}
+
+func (b *S3Backend) Put(ctx context.Context, key string, r io.Reader, meta PutMeta) error {
+	uploader := manager.NewUploader(b.Client, func(u *manager.Uploader) {
+		if b.PartSizeMb > 0 {
+			u.PartSize = int64(b.PartSizeMb) * 1024 * 1024
+		}
+		if b.Concurrency > 0 {
+			u.Concurrency = b.Concurrency
+		}
+	})
+
+	input := &s3.PutObjectInput{
+		Bucket:  aws.String(b.Bucket),
+		Key:     aws.String(key),
+		Body:    r,
+		Expires: meta.Expires, // cache expiration
+	}
+	if meta.ContentType != "" {
+		input.ContentType = aws.String(meta.ContentType)
+	}
+
+	_, err := uploader.Upload(ctx, input)
+	return err
+}
+
+func (b *S3Backend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := b.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+func (b *S3Backend) Presign(ctx context.Context, key string, ttl time.Duration, opts PresignOptions) (string, error) {
+	if ttl > maxPresignTTL {
+		ttl = maxPresignTTL
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(key),
+	}
+	if opts.ResponseContentDisposition != "" {
+		input.ResponseContentDisposition = aws.String(opts.ResponseContentDisposition)
+	}
+	if opts.ResponseContentType != "" {
+		input.ResponseContentType = aws.String(opts.ResponseContentType)
+	}
+
+	presignClient := s3.NewPresignClient(b.Client)
+	req, err := presignClient.PresignGetObject(ctx, input, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", err
+	}
+
+	return req.URL, nil
+}
+
+func (b *S3Backend) Delete(ctx context.Context, key string) error {
+	_, err := b.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (b *S3Backend) Stat(ctx context.Context, key string) (Info, error) {
+	out, err := b.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return Info{}, ErrNotFound
+		}
+		return Info{}, err
+	}
+
+	info := Info{}
+	if out.ContentLength != nil {
+		info.Size = *out.ContentLength
+	}
+	if out.LastModified != nil {
+		info.ModTime = *out.LastModified
+	}
+
+	return info, nil
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	appConfig "github.com/slaveofcode/hansip/utils/config"
+	"github.com/spf13/viper"
+)
+
+// New builds the Backend selected by the "storage.backend" config key
+// ("s3", "local" or "webdav"; defaults to "local"). s3Client is only used
+// when the s3 backend is selected, pass nil otherwise.
+func New(s3Client *s3.Client) (Backend, error) {
+	switch backend := viper.GetString("storage.backend"); backend {
+	case "s3":
+		s3Backend := NewS3Backend(s3Client, appConfig.GetS3Bucket())
+		s3Backend.PartSizeMb = viper.GetInt("s3.uploadPartSizeMb")
+		s3Backend.Concurrency = viper.GetInt("s3.uploadConcurrency")
+		return s3Backend, nil
+	case "webdav":
+		return NewWebDAVBackend(
+			viper.GetString("webdav.url"),
+			viper.GetString("webdav.username"),
+			viper.GetString("webdav.password"),
+		), nil
+	case "local", "":
+		return NewLocalBackend(filepath.FromSlash(viper.GetString("dirpaths.bundle"))), nil
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", backend)
+	}
+}
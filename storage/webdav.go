@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/studio-b12/gowebdav"
+)
+
+// WebDAVBackend stores archives on a remote WebDAV share, for self-hosters
+// who already have a NAS or Nextcloud instance instead of object storage.
+type WebDAVBackend struct {
+	client *gowebdav.Client
+}
+
+// NewWebDAVBackend builds a WebDAVBackend against baseURL, authenticating
+// with username/password when either is non-empty.
+func NewWebDAVBackend(baseURL, username, password string) *WebDAVBackend {
+	return &WebDAVBackend{client: gowebdav.NewClient(baseURL, username, password)}
+}
+
+func (b *WebDAVBackend) Put(ctx context.Context, key string, r io.Reader, meta PutMeta) error {
+	return b.client.WriteStream(key, r, 0o644)
+}
+
+func (b *WebDAVBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	rc, err := b.client.ReadStream(key)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return rc, err
+}
+
+// Presign always returns ErrPresignUnsupported, plain WebDAV has no notion
+// of a client-fetchable signed URL.
+func (b *WebDAVBackend) Presign(ctx context.Context, key string, ttl time.Duration, opts PresignOptions) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+func (b *WebDAVBackend) Delete(ctx context.Context, key string) error {
+	return b.client.Remove(key)
+}
+
+func (b *WebDAVBackend) Stat(ctx context.Context, key string) (Info, error) {
+	fi, err := b.client.Stat(key)
+	if os.IsNotExist(err) {
+		return Info{}, ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Info{Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}